@@ -0,0 +1,31 @@
+package client
+
+// CallOptions holds per-call overrides.
+type CallOptions struct {
+	// Compression is the algorithm, if any, to negotiate for this call.
+	// It's carried to rpcCodec via the X-Micro-Encoding header.
+	Compression string
+}
+
+// CallOption sets options for an individual Call.
+type CallOption func(*CallOptions)
+
+// WithCompression sets the compression algorithm to negotiate for this
+// call. The algorithm must be registered in DefaultCompressors, or it's
+// ignored by rpcCodec.Write.
+func WithCompression(algorithm string) CallOption {
+	return func(o *CallOptions) {
+		o.Compression = algorithm
+	}
+}
+
+// setCompressionHeader copies copts.Compression onto header as
+// X-Micro-Encoding, if set. NewStream calls this before opening an
+// rpcStream so a WithCompression call option actually reaches
+// rpcCodec.Write, which otherwise only ever sees a header a caller set by
+// hand.
+func setCompressionHeader(header map[string]string, copts CallOptions) {
+	if len(copts.Compression) > 0 {
+		header["X-Micro-Encoding"] = copts.Compression
+	}
+}
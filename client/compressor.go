@@ -0,0 +1,60 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+)
+
+// Compressor compresses and decompresses RPC payloads before they're
+// framed onto the wire.
+type Compressor interface {
+	String() string
+	Compress([]byte) ([]byte, error)
+	Decompress([]byte) ([]byte, error)
+}
+
+// DefaultCompressors holds the compressors rpcCodec can negotiate via the
+// X-Micro-Encoding header or WithCompression. Additional algorithms (e.g.
+// snappy, zstd) can be registered here by callers that vendor them.
+var DefaultCompressors = map[string]Compressor{
+	"gzip": gzipCompressor{},
+}
+
+// DefaultCompressionThreshold is the minimum payload size, in bytes, below
+// which rpcCodec.Write skips compression to avoid regressions on small
+// RPCs.
+var DefaultCompressionThreshold = 1024
+
+// shouldCompress reports whether a payload of size n is large enough to be
+// worth compressing under DefaultCompressionThreshold.
+func shouldCompress(n int) bool {
+	return n >= DefaultCompressionThreshold
+}
+
+type gzipCompressor struct{}
+
+func (gzipCompressor) String() string {
+	return "gzip"
+}
+
+func (gzipCompressor) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gzipCompressor) Decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
@@ -0,0 +1,36 @@
+package client
+
+import "testing"
+
+func TestGzipCompressorRoundTrip(t *testing.T) {
+	in := []byte("the quick brown fox jumps over the lazy dog, repeated for good measure")
+
+	compressed, err := gzipCompressor{}.Compress(in)
+	if err != nil {
+		t.Fatalf("Compress: %v", err)
+	}
+
+	out, err := gzipCompressor{}.Decompress(compressed)
+	if err != nil {
+		t.Fatalf("Decompress: %v", err)
+	}
+	if string(out) != string(in) {
+		t.Fatalf("got %q, want %q", out, in)
+	}
+}
+
+func TestShouldCompress(t *testing.T) {
+	orig := DefaultCompressionThreshold
+	DefaultCompressionThreshold = 1024
+	defer func() { DefaultCompressionThreshold = orig }()
+
+	if shouldCompress(1023) {
+		t.Fatal("expected payload under the threshold not to be compressed")
+	}
+	if !shouldCompress(1024) {
+		t.Fatal("expected payload at the threshold to be compressed")
+	}
+	if !shouldCompress(2048) {
+		t.Fatal("expected payload over the threshold to be compressed")
+	}
+}
@@ -0,0 +1,200 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/micro/go-micro/codec"
+	"github.com/micro/go-micro/transport"
+)
+
+// muxQueue is an unbounded FIFO queue of transport.Messages for a single
+// multiplexed stream. Unlike a fixed-size channel, push never blocks or
+// drops: muxCodec.run must never stall - or lose a message - waiting for
+// one slow stream's consumer to drain while it's demuxing for every other
+// stream sharing the same connection.
+type muxQueue struct {
+	mtx    sync.Mutex
+	cond   *sync.Cond
+	items  []*transport.Message
+	closed bool
+}
+
+func newMuxQueue() *muxQueue {
+	q := &muxQueue{}
+	q.cond = sync.NewCond(&q.mtx)
+	return q
+}
+
+func (q *muxQueue) push(m *transport.Message) {
+	q.mtx.Lock()
+	q.items = append(q.items, m)
+	q.mtx.Unlock()
+	q.cond.Signal()
+}
+
+// pop blocks until a message is available or the queue is closed and
+// drained, in which case it returns ok == false.
+func (q *muxQueue) pop() (m *transport.Message, ok bool) {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	for len(q.items) == 0 && !q.closed {
+		q.cond.Wait()
+	}
+	if len(q.items) == 0 {
+		return nil, false
+	}
+	m, q.items = q.items[0], q.items[1:]
+	return m, true
+}
+
+func (q *muxQueue) close() {
+	q.mtx.Lock()
+	q.closed = true
+	q.mtx.Unlock()
+	q.cond.Broadcast()
+}
+
+// muxCodec multiplexes many logical streams over a single underlying
+// transport.Client connection. A background goroutine reads every inbound
+// transport.Message and demuxes it to the stream it belongs to, keyed by
+// the X-Micro-Id header already written by rpcCodec.Write. This lets
+// hundreds of concurrent calls share one TCP/websocket connection instead
+// of each opening its own from the pool.
+type muxCodec struct {
+	transport.Client
+
+	seq uint64
+
+	mtx     sync.Mutex
+	streams map[string]*muxQueue
+}
+
+// newMuxCodec wraps client and starts demuxing inbound messages to the
+// streams opened against it via open.
+func newMuxCodec(client transport.Client) *muxCodec {
+	m := &muxCodec{
+		Client:  client,
+		streams: make(map[string]*muxQueue),
+	}
+	go m.run()
+	return m
+}
+
+// nextSeq returns a sequence number unique to this connection, used as the
+// X-Micro-Id for a new stream.
+func (m *muxCodec) nextSeq() string {
+	return fmt.Sprintf("%d", atomic.AddUint64(&m.seq, 1))
+}
+
+// open registers a new stream and returns the queue its inbound messages
+// will be delivered on.
+func (m *muxCodec) open(id string) *muxQueue {
+	q := newMuxQueue()
+	m.mtx.Lock()
+	m.streams[id] = q
+	m.mtx.Unlock()
+	return q
+}
+
+// removeStream unregisters a stream and closes its queue, so a consumer
+// blocked in muxStream.Recv is released with io.EOF instead of waiting on
+// a stream nothing will ever dispatch to again.
+func (m *muxCodec) removeStream(id string) {
+	m.mtx.Lock()
+	q, ok := m.streams[id]
+	delete(m.streams, id)
+	m.mtx.Unlock()
+	if ok {
+		q.close()
+	}
+}
+
+// run reads inbound messages off the shared connection and dispatches each
+// to its stream's queue until the connection errors out, at which point
+// every open stream is closed.
+func (m *muxCodec) run() {
+	for {
+		tm := new(transport.Message)
+		if err := m.Client.Recv(tm); err != nil {
+			m.mtx.Lock()
+			streams := m.streams
+			m.streams = make(map[string]*muxQueue)
+			m.mtx.Unlock()
+			for _, q := range streams {
+				q.close()
+			}
+			return
+		}
+
+		id := tm.Header["X-Micro-Id"]
+		eos := tm.Header["X-Micro-Error"] == lastStreamResponseError
+
+		m.mtx.Lock()
+		q, ok := m.streams[id]
+		if ok && eos {
+			delete(m.streams, id)
+		}
+		m.mtx.Unlock()
+		if !ok {
+			continue
+		}
+
+		// Deliver the message - including the EOS marker itself, so
+		// rpcCodec.Read can see its X-Micro-Error header and the caller
+		// can tell a clean stream end from a dropped connection - then
+		// close the queue once EOS has been queued for delivery.
+		q.push(tm)
+		if eos {
+			q.close()
+		}
+	}
+}
+
+// muxStream is a transport.Client handle scoped to a single multiplexed
+// stream of a muxCodec. It's what newRpcCodec is given as its client so
+// rpcCodec.Read blocks on the stream's own queue rather than racing every
+// other stream for m.Client.Recv.
+type muxStream struct {
+	id string
+	mc *muxCodec
+	q  *muxQueue
+}
+
+func (s *muxStream) Send(m *transport.Message) error {
+	return s.mc.Client.Send(m)
+}
+
+func (s *muxStream) Recv(m *transport.Message) error {
+	tm, ok := s.q.pop()
+	if !ok {
+		return io.EOF
+	}
+	*m = *tm
+	return nil
+}
+
+func (s *muxStream) Close() error {
+	s.mc.removeStream(s.id)
+	return nil
+}
+
+func (s *muxStream) Local() string {
+	return s.mc.Client.Local()
+}
+
+func (s *muxStream) Remote() string {
+	return s.mc.Client.Remote()
+}
+
+// newMuxRpcCodec opens a new multiplexed stream on mc and returns an
+// rpcCodec bound to it, plus the stream's X-Micro-Id, so callers can keep
+// using a single pooled connection for many concurrent requests and reuse
+// that id across every message they send on the stream.
+func newMuxRpcCodec(req *transport.Message, mc *muxCodec, c codec.NewCodec) (*rpcCodec, string) {
+	id := mc.nextSeq()
+	stream := &muxStream{id: id, mc: mc, q: mc.open(id)}
+	return newRpcCodec(req, stream, c), id
+}
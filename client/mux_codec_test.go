@@ -0,0 +1,142 @@
+package client
+
+import (
+	"io"
+	"testing"
+
+	"github.com/micro/go-micro/transport"
+)
+
+// scriptedClient is a transport.Client whose Recv plays back messages
+// handed to it via deliver, blocking until one is available. It lets
+// tests drive muxCodec's demux goroutine deterministically.
+type scriptedClient struct {
+	msgs chan *transport.Message
+}
+
+func newScriptedClient() *scriptedClient {
+	return &scriptedClient{msgs: make(chan *transport.Message, 16)}
+}
+
+func (c *scriptedClient) deliver(m *transport.Message) {
+	c.msgs <- m
+}
+
+func (c *scriptedClient) Send(m *transport.Message) error { return nil }
+
+func (c *scriptedClient) Recv(m *transport.Message) error {
+	tm, ok := <-c.msgs
+	if !ok {
+		return io.EOF
+	}
+	*m = *tm
+	return nil
+}
+
+func (c *scriptedClient) Close() error {
+	close(c.msgs)
+	return nil
+}
+
+func (c *scriptedClient) Local() string  { return "local" }
+func (c *scriptedClient) Remote() string { return "remote" }
+
+func TestMuxStreamDeliversMessagesInOrderWithoutDropping(t *testing.T) {
+	sc := newScriptedClient()
+	mc := newMuxCodec(sc)
+	defer sc.Close()
+
+	id := mc.nextSeq()
+	stream := &muxStream{id: id, mc: mc, q: mc.open(id)}
+
+	// Queue two messages for the same stream before anything reads
+	// either: muxQueue must hold both and deliver them in order, rather
+	// than dropping the second while the first is still unread.
+	sc.deliver(&transport.Message{Header: map[string]string{"X-Micro-Id": id}, Body: []byte("one")})
+	sc.deliver(&transport.Message{Header: map[string]string{"X-Micro-Id": id}, Body: []byte("two")})
+
+	var m1, m2 transport.Message
+	if err := stream.Recv(&m1); err != nil {
+		t.Fatalf("Recv 1: %v", err)
+	}
+	if err := stream.Recv(&m2); err != nil {
+		t.Fatalf("Recv 2: %v", err)
+	}
+	if string(m1.Body) != "one" || string(m2.Body) != "two" {
+		t.Fatalf("got %q, %q; want \"one\", \"two\"", m1.Body, m2.Body)
+	}
+}
+
+func TestMuxCodecDemuxesConcurrentStreams(t *testing.T) {
+	sc := newScriptedClient()
+	mc := newMuxCodec(sc)
+	defer sc.Close()
+
+	idA := mc.nextSeq()
+	streamA := &muxStream{id: idA, mc: mc, q: mc.open(idA)}
+	idB := mc.nextSeq()
+	streamB := &muxStream{id: idB, mc: mc, q: mc.open(idB)}
+
+	sc.deliver(&transport.Message{Header: map[string]string{"X-Micro-Id": idB}, Body: []byte("b")})
+	sc.deliver(&transport.Message{Header: map[string]string{"X-Micro-Id": idA}, Body: []byte("a")})
+
+	var mA, mB transport.Message
+	if err := streamA.Recv(&mA); err != nil {
+		t.Fatalf("Recv A: %v", err)
+	}
+	if err := streamB.Recv(&mB); err != nil {
+		t.Fatalf("Recv B: %v", err)
+	}
+	if string(mA.Body) != "a" || string(mB.Body) != "b" {
+		t.Fatalf("cross-delivered: A=%q B=%q", mA.Body, mB.Body)
+	}
+}
+
+func TestMuxStreamDeliversEOSThenEOF(t *testing.T) {
+	sc := newScriptedClient()
+	mc := newMuxCodec(sc)
+	defer sc.Close()
+
+	id := mc.nextSeq()
+	stream := &muxStream{id: id, mc: mc, q: mc.open(id)}
+
+	sc.deliver(&transport.Message{Header: map[string]string{
+		"X-Micro-Id":    id,
+		"X-Micro-Error": lastStreamResponseError,
+	}})
+
+	var m transport.Message
+	if err := stream.Recv(&m); err != nil {
+		t.Fatalf("expected the EOS message itself to be delivered, got error: %v", err)
+	}
+	if m.Header["X-Micro-Error"] != lastStreamResponseError {
+		t.Fatalf("expected the EOS header to be forwarded, got %+v", m.Header)
+	}
+
+	if err := stream.Recv(&m); err != io.EOF {
+		t.Fatalf("expected io.EOF once the stream is drained, got %v", err)
+	}
+}
+
+func TestMuxStreamCloseReleasesBlockedRecv(t *testing.T) {
+	sc := newScriptedClient()
+	mc := newMuxCodec(sc)
+	defer sc.Close()
+
+	id := mc.nextSeq()
+	stream := &muxStream{id: id, mc: mc, q: mc.open(id)}
+
+	done := make(chan error, 1)
+	go func() {
+		var m transport.Message
+		done <- stream.Recv(&m)
+	}()
+
+	if err := stream.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if err := <-done; err != io.EOF {
+		t.Fatalf("expected io.EOF after Close, got %v", err)
+	}
+}
@@ -0,0 +1,11 @@
+package client
+
+// Options configures a Client.
+type Options struct {
+	// Router, if set, is used to dispatch every call made with these
+	// Options instead of the default selector -> pool -> transport path.
+	Router Router
+}
+
+// Option sets a Client option.
+type Option func(*Options)
@@ -0,0 +1,18 @@
+package client
+
+// Request is the interface for a call made against a service, as seen by a
+// Router. It carries just enough to select and marshal onto a transport,
+// without exposing rpcCodec's internal wire representation.
+type Request interface {
+	// Service is the destination service name.
+	Service() string
+	// Method is the "Service.Method" RPC method being called.
+	Method() string
+	// Endpoint is the endpoint to invoke; for most transports this is the
+	// same as Method.
+	Endpoint() string
+	// ContentType is the content-type the request body is encoded with.
+	ContentType() string
+	// Body is the untyped request body passed to Call.
+	Body() interface{}
+}
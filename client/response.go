@@ -0,0 +1,39 @@
+package client
+
+import (
+	"github.com/micro/go-micro/codec"
+)
+
+// Response is a response to a call, as seen by a Stream. It exposes the
+// raw codec and undecoded body alongside the already-parsed headers, so
+// middleware and proxies can inspect trailers (e.g. X-Micro-Error) and
+// tracing headers and forward the body bytes on without a decode/re-encode
+// round-trip.
+type Response interface {
+	// Codec is the codec.Reader used to decode the response, for callers
+	// that want to read additional headers or decode the body
+	// themselves.
+	Codec() codec.Reader
+	// Header returns the response headers.
+	Header() map[string]string
+	// Read returns the undecoded response body.
+	Read() ([]byte, error)
+}
+
+type rpcResponse struct {
+	codec  codec.Reader
+	header map[string]string
+	body   []byte
+}
+
+func (r *rpcResponse) Codec() codec.Reader {
+	return r.codec
+}
+
+func (r *rpcResponse) Header() map[string]string {
+	return r.header
+}
+
+func (r *rpcResponse) Read() ([]byte, error) {
+	return r.body, nil
+}
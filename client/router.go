@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"io"
+)
+
+// Router controls how a Request is turned into a Response for a single
+// call, replacing the default selector -> pool -> transport path. It's a
+// first-class client option so callers can plug in service-mesh sidecar
+// routing, in-memory routing for tests, or custom load-balancing/retry
+// logic without reimplementing the whole Client.
+//
+// A Router is free to satisfy a request however it likes, including by
+// constructing its own transport.Client (or a synthetic in-process one
+// backed by an io.Pipe) and driving an rpcCodec directly.
+type Router interface {
+	SendRequest(ctx context.Context, req Request) (Response, error)
+}
+
+// WithRouter sets the Router used to dispatch calls, overriding the
+// default selector -> pool -> transport path for every call made with
+// these Options.
+func WithRouter(r Router) Option {
+	return func(o *Options) {
+		o.Router = r
+	}
+}
+
+// newRoutedStream hands req to router and wraps the Response it returns as
+// a Stream. NewStream calls this whenever Options.Router is set, so a
+// configured Router is always consulted ahead of the default rpcCodec
+// path rather than being silently ignored.
+func newRoutedStream(ctx context.Context, router Router, req Request) (Stream, error) {
+	rsp, err := router.SendRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &routedStream{ctx: ctx, req: req, rsp: rsp}, nil
+}
+
+// routedStream is a Stream over a Response a Router already produced in
+// full. Routers hand back one complete Response per call rather than a
+// live connection, so Recv yields it exactly once and then reports io.EOF.
+type routedStream struct {
+	ctx  context.Context
+	req  Request
+	rsp  Response
+	read bool
+	err  error
+}
+
+func (s *routedStream) Context() context.Context { return s.ctx }
+func (s *routedStream) Request() Request         { return s.req }
+func (s *routedStream) Response() Response       { return s.rsp }
+
+func (s *routedStream) Send(interface{}) error {
+	return io.ErrClosedPipe
+}
+
+func (s *routedStream) Recv(msg interface{}) error {
+	if s.read {
+		return io.EOF
+	}
+	s.read = true
+	if err := s.rsp.Codec().ReadBody(msg); err != nil {
+		s.err = err
+		return err
+	}
+	return nil
+}
+
+func (s *routedStream) Error() error { return s.err }
+func (s *routedStream) Close() error { return nil }
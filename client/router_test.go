@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/micro/go-micro/codec"
+)
+
+// testRequest is a minimal Request used across client package tests.
+type testRequest struct {
+	service     string
+	method      string
+	contentType string
+	body        interface{}
+}
+
+func (r *testRequest) Service() string     { return r.service }
+func (r *testRequest) Method() string      { return r.method }
+func (r *testRequest) Endpoint() string    { return r.method }
+func (r *testRequest) ContentType() string { return r.contentType }
+func (r *testRequest) Body() interface{}   { return r.body }
+
+// fakeReaderCodec is a minimal codec.Reader that hands back a fixed body,
+// for tests that need a Response without a real wire codec behind it.
+type fakeReaderCodec struct{ body []byte }
+
+func (f *fakeReaderCodec) ReadHeader(m *codec.Message, mt codec.MessageType) error { return nil }
+
+func (f *fakeReaderCodec) ReadBody(body interface{}) error {
+	b, ok := body.(*[]byte)
+	if !ok {
+		return nil
+	}
+	*b = f.body
+	return nil
+}
+
+type fakeResponse struct {
+	header map[string]string
+	body   []byte
+}
+
+func (r *fakeResponse) Codec() codec.Reader       { return &fakeReaderCodec{body: r.body} }
+func (r *fakeResponse) Header() map[string]string { return r.header }
+func (r *fakeResponse) Read() ([]byte, error)     { return r.body, nil }
+
+// fakeRouter is a Router that always returns a canned Response, recording
+// the Request it was called with.
+type fakeRouter struct {
+	rsp Response
+	err error
+	got Request
+}
+
+func (f *fakeRouter) SendRequest(ctx context.Context, req Request) (Response, error) {
+	f.got = req
+	return f.rsp, f.err
+}
+
+func TestNewStreamConsultsRouter(t *testing.T) {
+	router := &fakeRouter{rsp: &fakeResponse{body: []byte("hello")}}
+	req := &testRequest{service: "Foo", method: "Foo.Bar", contentType: "application/json"}
+
+	s, err := NewStream(context.Background(), Options{Router: router}, CallOptions{}, req, nil, nil)
+	if err != nil {
+		t.Fatalf("NewStream: %v", err)
+	}
+	if router.got != Request(req) {
+		t.Fatal("expected Router.SendRequest to be called with the given Request")
+	}
+
+	var out []byte
+	if err := s.Recv(&out); err != nil {
+		t.Fatalf("Recv: %v", err)
+	}
+	if string(out) != "hello" {
+		t.Fatalf("got %q, want %q", out, "hello")
+	}
+
+	if err := s.Recv(&out); err != io.EOF {
+		t.Fatalf("expected io.EOF on a second Recv, got %v", err)
+	}
+	if err := s.Send("anything"); err == nil {
+		t.Fatal("expected Send on a routed stream to fail: it already has its one Response")
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/micro/go-micro/codec"
 	raw "github.com/micro/go-micro/codec/bytes"
+	"github.com/micro/go-micro/codec/grpc"
 	"github.com/micro/go-micro/codec/json"
 	"github.com/micro/go-micro/codec/jsonrpc"
 	"github.com/micro/go-micro/codec/proto"
@@ -38,6 +39,12 @@ type rpcCodec struct {
 
 	req *transport.Message
 	buf *readWriteCloser
+
+	// lastHeader/lastBody hold the most recently read message's headers
+	// and undecoded body, so Response() can expose them without a
+	// decode/re-encode round-trip.
+	lastHeader map[string]string
+	lastBody   []byte
 }
 
 type readWriteCloser struct {
@@ -74,6 +81,9 @@ var (
 		"application/json-rpc":     jsonrpc.NewCodec,
 		"application/proto-rpc":    protorpc.NewCodec,
 		"application/octet-stream": raw.NewCodec,
+		"application/grpc":         grpc.NewCodec,
+		"application/grpc+proto":   grpc.NewCodec,
+		"application/grpc+json":    grpc.NewJSONCodec,
 	}
 )
 
@@ -122,7 +132,25 @@ func (c *rpcCodec) Write(req *request, body interface{}) error {
 	if err := c.codec.Write(m, body); err != nil {
 		return errors.InternalServerError("go.micro.client.codec", err.Error())
 	}
-	c.req.Body = c.buf.wbuf.Bytes()
+
+	payload := c.buf.wbuf.Bytes()
+	if enc := c.req.Header["X-Micro-Encoding"]; len(enc) > 0 {
+		cmp, ok := DefaultCompressors[enc]
+		if ok && shouldCompress(len(payload)) {
+			compressed, err := cmp.Compress(payload)
+			if err != nil {
+				return errors.InternalServerError("go.micro.client.codec", err.Error())
+			}
+			payload = compressed
+		} else {
+			// Too small to be worth compressing, or an unregistered
+			// algorithm: don't ship a header promising a body format we
+			// didn't actually produce, or Read will try to decompress
+			// plain bytes and fail.
+			delete(c.req.Header, "X-Micro-Encoding")
+		}
+	}
+	c.req.Body = payload
 	for k, v := range m.Header {
 		c.req.Header[k] = v
 	}
@@ -137,8 +165,20 @@ func (c *rpcCodec) Read(r *response, b interface{}) error {
 	if err := c.client.Recv(&m); err != nil {
 		return errors.InternalServerError("go.micro.client.transport", err.Error())
 	}
+	body := m.Body
+	if enc := m.Header["X-Micro-Encoding"]; len(enc) > 0 {
+		if cmp, ok := DefaultCompressors[enc]; ok {
+			decompressed, err := cmp.Decompress(body)
+			if err != nil {
+				return errors.InternalServerError("go.micro.client.codec", err.Error())
+			}
+			body = decompressed
+		}
+	}
 	c.buf.rbuf.Reset()
-	c.buf.rbuf.Write(m.Body)
+	c.buf.rbuf.Write(body)
+	c.lastHeader = m.Header
+	c.lastBody = body
 
 	var me codec.Message
 	// set headers
@@ -176,6 +216,12 @@ func (c *rpcCodec) Read(r *response, b interface{}) error {
 	return nil
 }
 
+// Response returns the most recently read message as a Response, giving
+// access to its raw, undecoded body alongside the codec used to decode it.
+func (c *rpcCodec) Response() Response {
+	return &rpcResponse{codec: c.codec, header: c.lastHeader, body: c.lastBody}
+}
+
 func (c *rpcCodec) Close() error {
 	c.buf.Close()
 	c.codec.Close()
@@ -0,0 +1,135 @@
+package client
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"sync"
+	"testing"
+
+	"github.com/micro/go-micro/codec"
+	"github.com/micro/go-micro/transport"
+)
+
+// fakeCodec is a minimal codec.Codec that treats message bodies as raw
+// []byte, so tests can exercise rpcCodec's compression handling without a
+// real wire codec.
+type fakeCodec struct {
+	rwc io.ReadWriteCloser
+}
+
+func newFakeCodec(rwc io.ReadWriteCloser) codec.Codec {
+	return &fakeCodec{rwc: rwc}
+}
+
+func (f *fakeCodec) Write(m *codec.Message, body interface{}) error {
+	b, _ := body.([]byte)
+	_, err := f.rwc.Write(b)
+	return err
+}
+
+func (f *fakeCodec) ReadHeader(m *codec.Message, mt codec.MessageType) error {
+	return nil
+}
+
+func (f *fakeCodec) ReadBody(body interface{}) error {
+	b, ok := body.(*[]byte)
+	if !ok {
+		return nil
+	}
+	data, err := ioutil.ReadAll(f.rwc)
+	if err != nil {
+		return err
+	}
+	*b = data
+	return nil
+}
+
+func (f *fakeCodec) Close() error   { return nil }
+func (f *fakeCodec) String() string { return "fake" }
+
+// fakeTransportClient is a loopback transport.Client: every Send is
+// replayed back verbatim on the next Recv, just like a server that echoes
+// headers and body unchanged.
+type fakeTransportClient struct {
+	mtx  sync.Mutex
+	sent []*transport.Message
+}
+
+func (f *fakeTransportClient) Send(m *transport.Message) error {
+	cp := transport.Message{Body: append([]byte(nil), m.Body...), Header: make(map[string]string, len(m.Header))}
+	for k, v := range m.Header {
+		cp.Header[k] = v
+	}
+	f.mtx.Lock()
+	f.sent = append(f.sent, &cp)
+	f.mtx.Unlock()
+	return nil
+}
+
+func (f *fakeTransportClient) Recv(m *transport.Message) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if len(f.sent) == 0 {
+		return io.EOF
+	}
+	next := f.sent[0]
+	f.sent = f.sent[1:]
+	*m = *next
+	return nil
+}
+
+func (f *fakeTransportClient) Close() error   { return nil }
+func (f *fakeTransportClient) Local() string  { return "local" }
+func (f *fakeTransportClient) Remote() string { return "remote" }
+
+func TestWriteClearsStaleEncodingHeaderBelowThreshold(t *testing.T) {
+	orig := DefaultCompressionThreshold
+	DefaultCompressionThreshold = 1024
+	defer func() { DefaultCompressionThreshold = orig }()
+
+	tm := &transport.Message{Header: map[string]string{"X-Micro-Encoding": "gzip"}}
+	rc := newRpcCodec(tm, &fakeTransportClient{}, newFakeCodec)
+
+	small := []byte("hi")
+	if err := rc.Write(&request{Service: "Foo", ServiceMethod: "Foo.Bar", Seq: "1"}, small); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	if enc := tm.Header["X-Micro-Encoding"]; enc != "" {
+		t.Fatalf("expected X-Micro-Encoding to be cleared for a sub-threshold payload, got %q", enc)
+	}
+
+	var out []byte
+	if err := rc.Read(&response{}, &out); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if !bytes.Equal(out, small) {
+		t.Fatalf("got %q, want %q", out, small)
+	}
+}
+
+func TestWriteCompressesAboveThreshold(t *testing.T) {
+	orig := DefaultCompressionThreshold
+	DefaultCompressionThreshold = 16
+	defer func() { DefaultCompressionThreshold = orig }()
+
+	tm := &transport.Message{Header: map[string]string{"X-Micro-Encoding": "gzip"}}
+	rc := newRpcCodec(tm, &fakeTransportClient{}, newFakeCodec)
+
+	large := bytes.Repeat([]byte("x"), 256)
+	if err := rc.Write(&request{Service: "Foo", ServiceMethod: "Foo.Bar", Seq: "1"}, large); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if enc := tm.Header["X-Micro-Encoding"]; enc != "gzip" {
+		t.Fatalf("expected X-Micro-Encoding to stay set for a compressed payload, got %q", enc)
+	}
+
+	var out []byte
+	if err := rc.Read(&response{}, &out); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if !bytes.Equal(out, large) {
+		t.Fatalf("got %d bytes, want %d", len(out), len(large))
+	}
+}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"context"
+	"io"
+
+	"github.com/micro/go-micro/codec"
+	"github.com/micro/go-micro/transport"
+)
+
+// NewStream opens a Stream for req. If opts.Router is set it's consulted
+// first and handles the whole call itself; otherwise an rpcStream is
+// opened as a new multiplexed stream on mc, sharing its single
+// underlying connection with every other stream opened against it.
+func NewStream(ctx context.Context, opts Options, copts CallOptions, req Request, mc *muxCodec, cf codec.NewCodec) (Stream, error) {
+	if opts.Router != nil {
+		return newRoutedStream(ctx, opts.Router, req)
+	}
+
+	header := map[string]string{"Content-Type": req.ContentType()}
+	setCompressionHeader(header, copts)
+
+	tm := &transport.Message{Header: header}
+	rc, id := newMuxRpcCodec(tm, mc, cf)
+	return &rpcStream{ctx: ctx, req: req, codec: rc, id: id}, nil
+}
+
+// rpcStream is a Stream backed by an rpcCodec multiplexed over a shared
+// transport connection. Every message it sends or receives carries the
+// same X-Micro-Id, the id muxCodec opened the underlying stream with.
+type rpcStream struct {
+	ctx   context.Context
+	req   Request
+	codec *rpcCodec
+	id    string
+	err   error
+}
+
+func (s *rpcStream) Context() context.Context { return s.ctx }
+func (s *rpcStream) Request() Request         { return s.req }
+func (s *rpcStream) Response() Response       { return s.codec.Response() }
+
+func (s *rpcStream) Send(msg interface{}) error {
+	err := s.codec.Write(&request{
+		Service:       s.req.Service(),
+		ServiceMethod: s.req.Method(),
+		Seq:           s.id,
+	}, msg)
+	if err != nil {
+		s.err = err
+	}
+	return err
+}
+
+func (s *rpcStream) Recv(msg interface{}) error {
+	var r response
+	if err := s.codec.Read(&r, msg); err != nil {
+		s.err = err
+		return err
+	}
+	// EOS is the sender telling us it's done, not a failure: report it
+	// the same way any other exhausted stream would be, so callers can
+	// tell a clean end from a real error.
+	if r.Error == lastStreamResponseError {
+		return io.EOF
+	}
+	if len(r.Error) > 0 {
+		s.err = serverError(r.Error)
+		return s.err
+	}
+	return nil
+}
+
+func (s *rpcStream) Error() error { return s.err }
+func (s *rpcStream) Close() error { return s.codec.Close() }
@@ -0,0 +1,52 @@
+package client
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/micro/go-micro/transport"
+)
+
+func TestNewStreamOpensMultiplexedRpcStream(t *testing.T) {
+	sc := newScriptedClient()
+	mc := newMuxCodec(sc)
+	defer sc.Close()
+
+	req := &testRequest{service: "Foo", method: "Foo.Bar", contentType: "application/octet-stream"}
+	s, err := NewStream(context.Background(), Options{}, CallOptions{}, req, mc, newFakeCodec)
+	if err != nil {
+		t.Fatalf("NewStream: %v", err)
+	}
+	rs, ok := s.(*rpcStream)
+	if !ok {
+		t.Fatalf("expected *rpcStream, got %T", s)
+	}
+
+	if err := rs.Send([]byte("ping")); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	sc.deliver(&transport.Message{
+		Header: map[string]string{"X-Micro-Id": rs.id},
+		Body:   []byte("pong"),
+	})
+
+	var out []byte
+	if err := rs.Recv(&out); err != nil {
+		t.Fatalf("Recv: %v", err)
+	}
+	if string(out) != "pong" {
+		t.Fatalf("got %q, want %q", out, "pong")
+	}
+
+	sc.deliver(&transport.Message{
+		Header: map[string]string{
+			"X-Micro-Id":    rs.id,
+			"X-Micro-Error": lastStreamResponseError,
+		},
+	})
+	if err := rs.Recv(&out); err != io.EOF {
+		t.Fatalf("expected io.EOF at end of stream, got %v", err)
+	}
+}
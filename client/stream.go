@@ -0,0 +1,26 @@
+package client
+
+import (
+	"context"
+)
+
+// Stream is the interface for a bidirectional streaming RPC call.
+type Stream interface {
+	// Context is the context used to start the stream.
+	Context() context.Context
+	// Request is the request made to start this stream.
+	Request() Request
+	// Response gives access to the last message read off the stream,
+	// including its raw headers and undecoded body. Useful for
+	// middleware and proxies that need to forward a response without a
+	// decode/re-encode round-trip.
+	Response() Response
+	// Send sends a message on the stream.
+	Send(interface{}) error
+	// Recv reads the next message off the stream into msg.
+	Recv(msg interface{}) error
+	// Error returns any error that occurred on the stream.
+	Error() error
+	// Close closes the stream.
+	Close() error
+}
@@ -0,0 +1,139 @@
+// Package grpc provides a codec.Codec that speaks the gRPC wire format:
+// a 1-byte compression flag, a 4-byte big-endian message length, followed
+// by the (optionally compressed) message bytes, as described at
+// https://grpc.io/docs/guides/wire.html. It lets go-micro clients call
+// real gRPC servers directly by setting WithContentType("application/grpc"),
+// "application/grpc+proto" or "application/grpc+json".
+package grpc
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/micro/go-micro/codec"
+)
+
+// Codec implements codec.Codec for the gRPC wire format.
+type Codec struct {
+	Conn io.ReadWriteCloser
+
+	// ContentType selects how message bodies are marshaled: protobuf for
+	// "application/grpc" and "application/grpc+proto", JSON for
+	// "application/grpc+json".
+	ContentType string
+
+	length uint32
+}
+
+// NewCodec returns a gRPC codec that marshals bodies as protobuf, for the
+// "application/grpc" and "application/grpc+proto" content types.
+func NewCodec(c io.ReadWriteCloser) codec.Codec {
+	return &Codec{Conn: c, ContentType: "application/grpc+proto"}
+}
+
+// NewJSONCodec returns a gRPC codec that marshals bodies as JSON, for the
+// "application/grpc+json" content type.
+func NewJSONCodec(c io.ReadWriteCloser) codec.Codec {
+	return &Codec{Conn: c, ContentType: "application/grpc+json"}
+}
+
+func (g *Codec) marshal(body interface{}) ([]byte, error) {
+	if body == nil {
+		return nil, nil
+	}
+	if g.ContentType == "application/grpc+json" {
+		return json.Marshal(body)
+	}
+	pb, ok := body.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("grpc codec: body does not implement proto.Message")
+	}
+	return proto.Marshal(pb)
+}
+
+func (g *Codec) unmarshal(data []byte, body interface{}) error {
+	if body == nil {
+		return nil
+	}
+	if g.ContentType == "application/grpc+json" {
+		// A zero-length body is a normal, valid gRPC response (e.g.
+		// google.protobuf.Empty, or any message whose fields are all
+		// default); json.Unmarshal rejects it outright, so leave body
+		// untouched instead of erroring on "unexpected end of JSON input".
+		if len(data) == 0 {
+			return nil
+		}
+		return json.Unmarshal(data, body)
+	}
+	pb, ok := body.(proto.Message)
+	if !ok {
+		return fmt.Errorf("grpc codec: body does not implement proto.Message")
+	}
+	return proto.Unmarshal(data, pb)
+}
+
+// Write frames body as a length-prefixed gRPC message and writes it to the
+// underlying connection.
+func (g *Codec) Write(m *codec.Message, body interface{}) error {
+	payload, err := g.marshal(body)
+	if err != nil {
+		return err
+	}
+
+	if m.Header == nil {
+		m.Header = make(map[string]string)
+	}
+	m.Header["grpc-encoding"] = "identity"
+
+	var hdr [5]byte
+	// compression flag; compression is negotiated via grpc-encoding
+	// rather than this bit, so it's always 0 (identity) for now.
+	binary.BigEndian.PutUint32(hdr[1:], uint32(len(payload)))
+	if _, err := g.Conn.Write(hdr[:]); err != nil {
+		return err
+	}
+	if len(payload) == 0 {
+		return nil
+	}
+	_, err = g.Conn.Write(payload)
+	return err
+}
+
+// ReadHeader reads the 5-byte gRPC frame header and surfaces grpc-status/
+// grpc-message as the message error.
+func (g *Codec) ReadHeader(m *codec.Message, mt codec.MessageType) error {
+	var hdr [5]byte
+	if _, err := io.ReadFull(g.Conn, hdr[:]); err != nil {
+		return err
+	}
+	g.length = binary.BigEndian.Uint32(hdr[1:])
+
+	if m.Header == nil {
+		m.Header = make(map[string]string)
+	}
+	if status := m.Header["grpc-status"]; len(status) > 0 && status != "0" {
+		m.Error = m.Header["grpc-message"]
+	}
+	return nil
+}
+
+// ReadBody reads the gRPC frame payload and unmarshals it into body.
+func (g *Codec) ReadBody(body interface{}) error {
+	payload, err := ioutil.ReadAll(io.LimitReader(g.Conn, int64(g.length)))
+	if err != nil {
+		return err
+	}
+	return g.unmarshal(payload, body)
+}
+
+func (g *Codec) Close() error {
+	return g.Conn.Close()
+}
+
+func (g *Codec) String() string {
+	return "grpc"
+}
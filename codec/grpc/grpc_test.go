@@ -0,0 +1,79 @@
+package grpc
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/micro/go-micro/codec"
+)
+
+type nopCloser struct{ *bytes.Buffer }
+
+func (nopCloser) Close() error { return nil }
+
+func TestJSONCodecRoundTrip(t *testing.T) {
+	buf := &bytes.Buffer{}
+	c := NewJSONCodec(nopCloser{buf})
+
+	type payload struct {
+		Foo string `json:"foo"`
+	}
+
+	in := &payload{Foo: "bar"}
+	if err := c.Write(&codec.Message{Type: codec.Request}, in); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if buf.Len() < 5 {
+		t.Fatalf("expected at least a 5 byte frame header, got %d bytes", buf.Len())
+	}
+
+	var out payload
+	if err := c.ReadHeader(&codec.Message{}, codec.Response); err != nil {
+		t.Fatalf("ReadHeader: %v", err)
+	}
+	if err := c.ReadBody(&out); err != nil {
+		t.Fatalf("ReadBody: %v", err)
+	}
+	if out.Foo != in.Foo {
+		t.Fatalf("got %+v, want %+v", out, in)
+	}
+}
+
+func TestReadHeaderSurfacesGRPCStatus(t *testing.T) {
+	buf := &bytes.Buffer{}
+	buf.Write([]byte{0, 0, 0, 0, 0}) // 5-byte frame header, zero-length payload
+
+	c := NewJSONCodec(nopCloser{buf})
+	m := &codec.Message{Header: map[string]string{
+		"grpc-status":  "2",
+		"grpc-message": "boom",
+	}}
+
+	if err := c.ReadHeader(m, codec.Response); err != nil {
+		t.Fatalf("ReadHeader: %v", err)
+	}
+	if m.Error != "boom" {
+		t.Fatalf("got Error %q, want %q", m.Error, "boom")
+	}
+}
+
+func TestJSONCodecReadBodyEmptyPayload(t *testing.T) {
+	buf := &bytes.Buffer{}
+	buf.Write([]byte{0, 0, 0, 0, 0}) // 5-byte frame header, zero-length payload
+
+	c := NewJSONCodec(nopCloser{buf})
+	if err := c.ReadHeader(&codec.Message{}, codec.Response); err != nil {
+		t.Fatalf("ReadHeader: %v", err)
+	}
+
+	type payload struct {
+		Foo string `json:"foo"`
+	}
+	var out payload
+	if err := c.ReadBody(&out); err != nil {
+		t.Fatalf("ReadBody on an empty gRPC body should succeed, got: %v", err)
+	}
+	if out != (payload{}) {
+		t.Fatalf("expected a zero-value body, got %+v", out)
+	}
+}